@@ -0,0 +1,141 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package main implements runmqserver, which runs and supervises a queue
+// manager inside the container.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// defaultHealthPort is the port used for the HTTP health endpoints when
+// MQ_HEALTH_PORT isn't set.
+const defaultHealthPort = "9157"
+
+// healthCheckResult is the JSON body returned by the HTTP health endpoints.
+// It mirrors the rc/output reported by the underlying chkmq* scripts, so
+// that a failure can be diagnosed without a docker exec.
+type healthCheckResult struct {
+	Check  string `json:"check"`
+	RC     int    `json:"rc"`
+	Reason string `json:"reason"`
+}
+
+// healthEndpoint maps an HTTP path to the command-line check it wraps.
+type healthEndpoint struct {
+	path    string
+	command string
+}
+
+// healthEndpoints lists the endpoints exposed by startHealthServer.  They
+// correspond directly to the chkmqready/chkmqhealthy/chkmqstarted checks
+// (the first two are the ones the existing tests run via docker exec), so
+// that Kubernetes startupProbe,
+// readinessProbe and livenessProbe can all be satisfied without a docker
+// exec.
+var healthEndpoints = []healthEndpoint{
+	{"/ready", "chkmqready"},
+	{"/live", "chkmqhealthy"},
+	{"/started", "chkmqstarted"},
+}
+
+// healthHTTPEnabled returns true if the HTTP health endpoints should be
+// served, as requested via MQ_ENABLE_HEALTH_HTTP.  They're opt-in, so that
+// existing images which don't expose the port keep their current behaviour.
+func healthHTTPEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("MQ_ENABLE_HEALTH_HTTP"))
+	return err == nil && enabled
+}
+
+// healthHTTPPort returns the configured health port, or defaultHealthPort.
+func healthHTTPPort() string {
+	if p := os.Getenv("MQ_HEALTH_PORT"); p != "" {
+		return p
+	}
+	return defaultHealthPort
+}
+
+// runHealthCheck executes the named check binary and translates its exit
+// code and output into a healthCheckResult.
+func runHealthCheck(ctx context.Context, command string) healthCheckResult {
+	out, err := exec.CommandContext(ctx, command).CombinedOutput()
+	result := healthCheckResult{
+		Check:  command,
+		RC:     0,
+		Reason: "OK",
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.RC = exitErr.ExitCode()
+		} else {
+			result.RC = -1
+		}
+		result.Reason = string(out)
+		if result.Reason == "" {
+			result.Reason = err.Error()
+		}
+	}
+	return result
+}
+
+// healthHandler returns an http.HandlerFunc which runs command and reports
+// its result as a JSON body, with a 200 or 503 status code.
+func healthHandler(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := runHealthCheck(r.Context(), command)
+		w.Header().Set("Content-Type", "application/json")
+		if result.RC != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("Error writing health check response: %v", err)
+		}
+	}
+}
+
+// startHealthServer starts the HTTP readiness/liveness/startup server, if
+// enabled via MQ_ENABLE_HEALTH_HTTP.  It must be called only once MQSC
+// processing has completed, so that a Kubernetes startupProbe/readinessProbe
+// never observes a server that's listening before configuration has
+// actually been applied.
+func startHealthServer() {
+	if !healthHTTPEnabled() {
+		return
+	}
+	mux := http.NewServeMux()
+	for _, e := range healthEndpoints {
+		mux.HandleFunc(e.path, healthHandler(e.command))
+	}
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%v", healthHTTPPort()),
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Error running health HTTP server: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,124 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gabrielps/mq-container/internal/logsink"
+)
+
+// errorLogPollInterval is how often the active error log is checked for
+// new entries to forward.
+const errorLogPollInterval = 2 * time.Second
+
+// newLogSinkFunc is indirected so tests can stub it without depending on
+// MQ_LOG_SINK / MQ_LOG_SINK_URL.
+var newLogSinkFunc = logsink.NewFromEnv
+
+// errorLogPath returns the active, JSON-formatted error log runmqserver
+// mirrors to the configured log sink.  The queue manager itself already
+// mirrors this file to stdout; this just gives it a second destination.
+func errorLogPath(qmgrName string) string {
+	return fmt.Sprintf("/var/mqm/qmgrs/%v/errors/AMQERR01.json", qmgrName)
+}
+
+// startLogSink builds the log sink selected by MQ_LOG_SINK and starts
+// forwarding the queue manager's error log to it in the background. The
+// returned io.Closer stops forwarding and closes the sink; it must be
+// called once, during shutdown.
+func startLogSink(qmgrName string) (io.Closer, error) {
+	sink, err := newLogSinkFunc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start log sink: %v", err)
+	}
+	done := make(chan struct{})
+	go mirrorErrorLog(errorLogPath(qmgrName), sink, done)
+	return &logSinkCloser{sink: sink, done: done}, nil
+}
+
+// logSinkCloser stops mirrorErrorLog and closes the underlying sink.
+type logSinkCloser struct {
+	sink logsink.Sink
+	done chan struct{}
+}
+
+func (c *logSinkCloser) Close() error {
+	close(c.done)
+	return c.sink.Close()
+}
+
+// mirrorErrorLog polls path for newly appended lines and forwards each one
+// to sink, until done is closed.  It tolerates path not existing yet (the
+// queue manager may not have logged anything) and log rotation truncating
+// the file back to a fresh AMQERR01.json.
+func mirrorErrorLog(path string, sink logsink.Sink, done <-chan struct{}) {
+	ticker := time.NewTicker(errorLogPollInterval)
+	defer ticker.Stop()
+	sent := 0
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			sent = tailErrorLog(path, sink, sent)
+		}
+	}
+}
+
+// tailErrorLog sends every line in path beyond the first sent lines to
+// sink, and returns the new total number of lines in the file.  If the
+// file has fewer lines than sent, it's treated as freshly rotated and
+// forwarded from the start.
+func tailErrorLog(path string, sink logsink.Sink, sent int) int {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Not logged anything yet, or mid-rotation: start from the
+			// beginning once it reappears.
+			return 0
+		}
+		// A transient error (e.g. a permission blip) shouldn't reset the
+		// tracked offset, or the next successful read would re-send every
+		// line already forwarded.
+		log.Printf("Error opening %v for log sink forwarding: %v", path, err)
+		return sent
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Error reading %v for log sink forwarding: %v", path, err)
+		return sent
+	}
+	if len(lines) < sent {
+		sent = 0
+	}
+	for _, line := range lines[sent:] {
+		sink.Send([]byte(line))
+	}
+	return len(lines)
+}
@@ -0,0 +1,120 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabrielps/mq-container/internal/logsink"
+)
+
+type fakeSink struct {
+	sent   [][]byte
+	closed bool
+}
+
+func (s *fakeSink) Send(entry []byte) { s.sent = append(s.sent, append([]byte(nil), entry...)) }
+func (s *fakeSink) Close() error      { s.closed = true; return nil }
+
+// TestTailErrorLogSendsOnlyNewLines checks that tailErrorLog forwards
+// lines appended since the last call, not ones already sent.
+func TestTailErrorLogSendsOnlyNewLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AMQERR01.json")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	sink := &fakeSink{}
+
+	sent := tailErrorLog(path, sink, 0)
+	if sent != 2 {
+		t.Fatalf("Expected 2 lines read, got %v", sent)
+	}
+	if len(sink.sent) != 2 {
+		t.Fatalf("Expected 2 lines forwarded, got %v", len(sink.sent))
+	}
+
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	sent = tailErrorLog(path, sink, sent)
+	if sent != 3 {
+		t.Fatalf("Expected 3 lines read, got %v", sent)
+	}
+	if len(sink.sent) != 3 {
+		t.Fatalf("Expected only the new line to be forwarded, got %v total", len(sink.sent))
+	}
+	if string(sink.sent[2]) != "three" {
+		t.Errorf("Expected the new line to be %q, got %q", "three", sink.sent[2])
+	}
+}
+
+// TestTailErrorLogHandlesRotation checks that a file with fewer lines than
+// already sent (log rotation truncating it to a fresh file) is forwarded
+// from the start, instead of being treated as having nothing new.
+func TestTailErrorLogHandlesRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AMQERR01.json")
+	sink := &fakeSink{}
+
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	sent := tailErrorLog(path, sink, 0)
+
+	if err := os.WriteFile(path, []byte("four\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	sent = tailErrorLog(path, sink, sent)
+	if sent != 1 {
+		t.Fatalf("Expected 1 line read after rotation, got %v", sent)
+	}
+	if len(sink.sent) != 4 {
+		t.Fatalf("Expected the rotated file's line to be forwarded, got %v total", len(sink.sent))
+	}
+	if string(sink.sent[3]) != "four" {
+		t.Errorf("Expected the rotated line to be %q, got %q", "four", sink.sent[3])
+	}
+}
+
+// TestTailErrorLogMissingFile checks that a not-yet-created error log is
+// tolerated, rather than being treated as an error.
+func TestTailErrorLogMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AMQERR01.json")
+	sink := &fakeSink{}
+	if sent := tailErrorLog(path, sink, 0); sent != 0 {
+		t.Errorf("Expected 0 lines read for a missing file, got %v", sent)
+	}
+	if len(sink.sent) != 0 {
+		t.Errorf("Expected nothing forwarded for a missing file, got %v", len(sink.sent))
+	}
+}
+
+// TestStartLogSinkWrapsNewLogSinkError checks that a failure building the
+// sink (for example, an invalid MQ_LOG_SINK) is reported back to the
+// caller instead of panicking or silently running without a sink.
+func TestStartLogSinkWrapsNewLogSinkError(t *testing.T) {
+	origNewLogSink := newLogSinkFunc
+	defer func() { newLogSinkFunc = origNewLogSink }()
+	newLogSinkFunc = func() (logsink.Sink, error) {
+		return nil, errBoom
+	}
+
+	if _, err := startLogSink("qm1"); err == nil {
+		t.Fatal("Expected startLogSink to propagate the error from newLogSinkFunc")
+	}
+}
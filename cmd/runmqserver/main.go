@@ -0,0 +1,152 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gabrielps/mq-container/internal/mqtemplate"
+)
+
+// prepareReadOnlyRootFunc, mqscConfigDirFunc, copyReadOnlyFunc,
+// renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc and
+// handleSIGTERMFunc are indirected through variables so that startup()'s
+// and waitForShutdown()'s ordering can be covered by a unit test without
+// actually touching the filesystem, rendering templates, invoking
+// runmqsc, binding a port, or running endmqm.
+var (
+	prepareReadOnlyRootFunc = prepareReadOnlyRoot
+	mqscConfigDirFunc       = mqscConfigDir
+	copyReadOnlyFunc        = copyReadOnly
+	renderTemplatesFunc     = mqtemplate.RenderAll
+	applyMQSCFunc           = applyMQSC
+	startHealthServerFunc   = startHealthServer
+	startMetricsServerFunc  = startMetricsServer
+	startLogSinkFunc        = startLogSink
+	handleSIGTERMFunc       = handleSIGTERM
+)
+
+// startup runs runmqserver's startup sequence: prepare the writable
+// overlay needed for a read-only root filesystem, render any .mqsc.tmpl/
+// .ini.tmpl files, apply the queue manager's MQSC configuration, then
+// bring up the optional HTTP health and metrics endpoints.  Each step must
+// run in this order: the overlay must exist before anything is rendered
+// into it, rendering must run before applyMQSCFunc, and
+// startHealthServerFunc must run after it, so that a Kubernetes
+// startupProbe/readinessProbe polling /ready never observes a server
+// that's listening before configuration has actually been applied.  The
+// metrics exporter has no such ordering requirement, since scrape
+// failures just leave stale values in place rather than blocking
+// anything.
+func startup(qmgrName string) error {
+	if err := prepareReadOnlyRootFunc(); err != nil {
+		return err
+	}
+	srcDir, outDir := mqscConfigDirFunc()
+	if outDir != srcDir {
+		if err := copyReadOnlyFunc(srcDir, outDir); err != nil {
+			return err
+		}
+	}
+	if err := renderTemplatesFunc(srcDir, outDir); err != nil {
+		return err
+	}
+	if err := applyMQSCFunc(qmgrName, outDir); err != nil {
+		return err
+	}
+	startHealthServerFunc()
+	startMetricsServerFunc(qmgrName)
+	return nil
+}
+
+func main() {
+	qmgrName := queueManagerName()
+	if err := startup(qmgrName); err != nil {
+		log.Printf("Termination message: %v", err)
+		os.Exit(1)
+	}
+	logSink, err := startLogSinkFunc(qmgrName)
+	if err != nil {
+		log.Printf("Termination message: %v", err)
+		os.Exit(1)
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM)
+	err = waitForShutdown(qmgrName, sigCh)
+	logSink.Close()
+	if err != nil {
+		log.Printf("Termination message: %v", err)
+		os.Exit(1)
+	}
+}
+
+// waitForShutdown blocks until sigCh receives SIGTERM, then runs the
+// queue manager's graceful shutdown (see shutdown.go), returning any error
+// from it so main can propagate a non-zero exit code.
+func waitForShutdown(qmgrName string, sigCh <-chan os.Signal) error {
+	<-sigCh
+	return handleSIGTERMFunc(qmgrName)
+}
+
+// queueManagerName returns MQ_QMGR_NAME, falling back to the hostname, with
+// hyphens stripped in either case (the same sanitization dspmq reports for
+// a hostname-derived name).
+func queueManagerName() string {
+	name := os.Getenv("MQ_QMGR_NAME")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// applyMQSC runs every *.mqsc file under dir against qmgrName, in name
+// order.  This is the point at which "MQSC processing" is considered
+// complete.
+func applyMQSC(qmgrName string, dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.mqsc"))
+	if err != nil {
+		return err
+	}
+	for _, file := range matches {
+		if err := runMQSCFile(qmgrName, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runMQSCFile(qmgrName string, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %v: %v", file, err)
+	}
+	defer f.Close()
+	cmd := exec.Command("runmqsc", qmgrName)
+	cmd.Stdin = f
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply %v: %v: %v", file, err, string(out))
+	}
+	return nil
+}
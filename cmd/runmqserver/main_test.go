@@ -0,0 +1,238 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+// TestStartupOrder checks that startup() prepares the read-only root
+// overlay and seeds it before rendering templates, renders templates
+// before applying MQSC, and only starts the HTTP health server after MQSC
+// has been applied, so that a Kubernetes startupProbe/readinessProbe can
+// never observe /ready before configuration has actually been processed.
+func TestStartupOrder(t *testing.T) {
+	origPrepareReadOnlyRoot, origMQSCConfigDir, origCopyReadOnly, origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer :=
+		prepareReadOnlyRootFunc, mqscConfigDirFunc, copyReadOnlyFunc, renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc
+	defer func() {
+		prepareReadOnlyRootFunc, mqscConfigDirFunc, copyReadOnlyFunc, renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc =
+			origPrepareReadOnlyRoot, origMQSCConfigDir, origCopyReadOnly, origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer
+	}()
+
+	var order []string
+	prepareReadOnlyRootFunc = func() error {
+		order = append(order, "readonlyroot")
+		return nil
+	}
+	mqscConfigDirFunc = func() (string, string) {
+		return "/etc/mqm", "/run/mqm/etc-overlay"
+	}
+	copyReadOnlyFunc = func(srcDir string, dstDir string) error {
+		order = append(order, "copy")
+		return nil
+	}
+	renderTemplatesFunc = func(srcDir string, outDir string) error {
+		order = append(order, "templates")
+		return nil
+	}
+	applyMQSCFunc = func(qmgrName string, dir string) error {
+		order = append(order, "mqsc")
+		return nil
+	}
+	startHealthServerFunc = func() {
+		order = append(order, "health")
+	}
+	startMetricsServerFunc = func(qmgrName string) {
+		order = append(order, "metrics")
+	}
+
+	if err := startup("qm1"); err != nil {
+		t.Fatalf("Expected startup to succeed, got %v", err)
+	}
+	want := []string{"readonlyroot", "copy", "templates", "mqsc", "health", "metrics"}
+	if len(order) != len(want) {
+		t.Fatalf("Expected call order %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("Expected call order %v, got %v", want, order)
+		}
+	}
+}
+
+// TestStartupSkipsCopyWhenSameDir checks that copyReadOnlyFunc isn't
+// called at all when mqscConfigDirFunc reports a writable root (source
+// and rendered directories are the same), since there's nothing to seed.
+func TestStartupSkipsCopyWhenSameDir(t *testing.T) {
+	origPrepareReadOnlyRoot, origMQSCConfigDir, origCopyReadOnly, origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer :=
+		prepareReadOnlyRootFunc, mqscConfigDirFunc, copyReadOnlyFunc, renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc
+	defer func() {
+		prepareReadOnlyRootFunc, mqscConfigDirFunc, copyReadOnlyFunc, renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc =
+			origPrepareReadOnlyRoot, origMQSCConfigDir, origCopyReadOnly, origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer
+	}()
+
+	prepareReadOnlyRootFunc = func() error { return nil }
+	mqscConfigDirFunc = func() (string, string) {
+		return "/etc/mqm", "/etc/mqm"
+	}
+	copyCalled := false
+	copyReadOnlyFunc = func(srcDir string, dstDir string) error {
+		copyCalled = true
+		return nil
+	}
+	renderTemplatesFunc = func(srcDir string, outDir string) error { return nil }
+	applyMQSCFunc = func(qmgrName string, dir string) error { return nil }
+	startHealthServerFunc = func() {}
+	startMetricsServerFunc = func(qmgrName string) {}
+
+	if err := startup("qm1"); err != nil {
+		t.Fatalf("Expected startup to succeed, got %v", err)
+	}
+	if copyCalled {
+		t.Error("Expected copyReadOnly not to be called when srcDir and outDir are the same")
+	}
+}
+
+// TestStartupStopsOnReadOnlyRootError checks that a failure preparing the
+// read-only root overlay prevents anything else from running.
+func TestStartupStopsOnReadOnlyRootError(t *testing.T) {
+	origPrepareReadOnlyRoot, origRenderTemplates := prepareReadOnlyRootFunc, renderTemplatesFunc
+	defer func() { prepareReadOnlyRootFunc, renderTemplatesFunc = origPrepareReadOnlyRoot, origRenderTemplates }()
+
+	templatesRendered := false
+	prepareReadOnlyRootFunc = func() error {
+		return errBoom
+	}
+	renderTemplatesFunc = func(srcDir string, outDir string) error {
+		templatesRendered = true
+		return nil
+	}
+
+	if err := startup("qm1"); err == nil {
+		t.Fatal("Expected startup to return an error")
+	}
+	if templatesRendered {
+		t.Error("Expected templates not to be rendered when preparing the read-only root fails")
+	}
+}
+
+// TestStartupStopsOnMQSCError checks that a failure applying MQSC prevents
+// the health server from starting at all.
+func TestStartupStopsOnMQSCError(t *testing.T) {
+	origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer :=
+		renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc
+	defer func() {
+		renderTemplatesFunc, applyMQSCFunc, startHealthServerFunc, startMetricsServerFunc =
+			origRenderTemplates, origApplyMQSC, origStartHealthServer, origStartMetricsServer
+	}()
+
+	healthStarted, metricsStarted := false, false
+	renderTemplatesFunc = func(srcDir string, outDir string) error {
+		return nil
+	}
+	applyMQSCFunc = func(qmgrName string, dir string) error {
+		return errBoom
+	}
+	startHealthServerFunc = func() {
+		healthStarted = true
+	}
+	startMetricsServerFunc = func(qmgrName string) {
+		metricsStarted = true
+	}
+
+	if err := startup("qm1"); err == nil {
+		t.Fatal("Expected startup to return an error")
+	}
+	if healthStarted {
+		t.Error("Expected health server not to start when MQSC fails")
+	}
+	if metricsStarted {
+		t.Error("Expected metrics server not to start when MQSC fails")
+	}
+}
+
+// TestStartupStopsOnTemplateError checks that a failure rendering
+// templates prevents MQSC from being applied at all.
+func TestStartupStopsOnTemplateError(t *testing.T) {
+	origRenderTemplates, origApplyMQSC := renderTemplatesFunc, applyMQSCFunc
+	defer func() {
+		renderTemplatesFunc, applyMQSCFunc = origRenderTemplates, origApplyMQSC
+	}()
+
+	mqscApplied := false
+	renderTemplatesFunc = func(srcDir string, outDir string) error {
+		return errBoom
+	}
+	applyMQSCFunc = func(qmgrName string, dir string) error {
+		mqscApplied = true
+		return nil
+	}
+
+	if err := startup("qm1"); err == nil {
+		t.Fatal("Expected startup to return an error")
+	}
+	if mqscApplied {
+		t.Error("Expected MQSC not to be applied when template rendering fails")
+	}
+}
+
+// TestWaitForShutdownRunsHandleSIGTERM checks that waitForShutdown blocks
+// until a signal arrives, then runs the queue manager's graceful shutdown.
+func TestWaitForShutdownRunsHandleSIGTERM(t *testing.T) {
+	origHandleSIGTERM := handleSIGTERMFunc
+	defer func() { handleSIGTERMFunc = origHandleSIGTERM }()
+
+	var gotQmgrName string
+	handleSIGTERMFunc = func(qmgrName string) error {
+		gotQmgrName = qmgrName
+		return nil
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	sigCh <- syscall.SIGTERM
+	if err := waitForShutdown("qm1", sigCh); err != nil {
+		t.Fatalf("Expected waitForShutdown to succeed, got %v", err)
+	}
+	if gotQmgrName != "qm1" {
+		t.Errorf("Expected handleSIGTERM to run with qmgrName=qm1, got %v", gotQmgrName)
+	}
+}
+
+// TestWaitForShutdownPropagatesError checks that a failed shutdown is
+// reported back to main, so it can exit non-zero.
+func TestWaitForShutdownPropagatesError(t *testing.T) {
+	origHandleSIGTERM := handleSIGTERMFunc
+	defer func() { handleSIGTERMFunc = origHandleSIGTERM }()
+
+	handleSIGTERMFunc = func(qmgrName string) error {
+		return errBoom
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	sigCh <- syscall.SIGTERM
+	if err := waitForShutdown("qm1", sigCh); err == nil {
+		t.Fatal("Expected waitForShutdown to propagate the shutdown error")
+	}
+}
+
+var errBoom = errTest("boom")
+
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
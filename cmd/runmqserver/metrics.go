@@ -0,0 +1,345 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/gabrielps/mq-container/internal/logsink"
+)
+
+// defaultMetricsPort is the port used for the Prometheus /metrics endpoint
+// when MQ_METRICS_PORT isn't set.
+const defaultMetricsPort = "9308"
+
+// defaultMetricsInterval is the polling interval used when
+// MQ_METRICS_INTERVAL isn't set.
+const defaultMetricsInterval = 10 * time.Second
+
+var (
+	queueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "depth",
+		Help: "Current depth of the queue",
+	}, []string{"qmgr", "queue"})
+	queueMaxDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "max_depth",
+		Help: "Maximum configured depth of the queue",
+	}, []string{"qmgr", "queue"})
+	queueOpenInputCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "open_input_count",
+		Help: "Number of handles currently open for input against the queue",
+	}, []string{"qmgr", "queue"})
+	queueOpenOutputCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "open_output_count",
+		Help: "Number of handles currently open for output against the queue",
+	}, []string{"qmgr", "queue"})
+	queueEnqueueCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "msg_enqueue_count",
+		Help: "Number of messages put to the queue since the queue manager started",
+	}, []string{"qmgr", "queue"})
+	queueDequeueCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "queue", Name: "msg_dequeue_count",
+		Help: "Number of messages got from the queue since the queue manager started",
+	}, []string{"qmgr", "queue"})
+	channelStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "channel", Name: "status",
+		Help: "Current status of the channel, using the MQCHS_* values reported by runmqsc",
+	}, []string{"qmgr", "channel"})
+	listenerStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "listener", Name: "status",
+		Help: "Current status of the listener, using the MQSVC_STATUS_* values reported by runmqsc",
+	}, []string{"qmgr", "listener"})
+	qmgrUptimeSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mq", Subsystem: "qmgr", Name: "uptime_seconds",
+		Help: "Time in seconds since the queue manager was started",
+	}, []string{"qmgr"})
+	logSinkDroppedTotal = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: "mq", Subsystem: "log_sink", Name: "dropped_total",
+		Help: "Number of log entries dropped because the configured log sink couldn't keep up",
+	}, func() float64 { return float64(logsink.DroppedTotal()) })
+)
+
+func init() {
+	prometheus.MustRegister(
+		queueDepth, queueMaxDepth, queueOpenInputCount, queueOpenOutputCount,
+		queueEnqueueCount, queueDequeueCount, channelStatus, listenerStatus, qmgrUptimeSeconds,
+		logSinkDroppedTotal,
+	)
+}
+
+// metricsEnabled returns true if the Prometheus exporter should run, as
+// requested via MQ_ENABLE_METRICS.
+func metricsEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("MQ_ENABLE_METRICS"))
+	return err == nil && enabled
+}
+
+// metricsPort returns the configured metrics port, or defaultMetricsPort.
+func metricsPort() string {
+	if p := os.Getenv("MQ_METRICS_PORT"); p != "" {
+		return p
+	}
+	return defaultMetricsPort
+}
+
+// metricsInterval returns the configured polling interval, or
+// defaultMetricsInterval.
+func metricsInterval() time.Duration {
+	if v := os.Getenv("MQ_METRICS_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultMetricsInterval
+}
+
+// startMetricsServer starts the Prometheus /metrics HTTP server and the
+// background poller that keeps it populated, if enabled via
+// MQ_ENABLE_METRICS.  A failed scrape (for example while the queue manager
+// is restarting) is logged and retried on the next tick; it never blocks
+// readiness.
+func startMetricsServer(qmgrName string) {
+	if !metricsEnabled() {
+		return
+	}
+	go pollMetrics(qmgrName, metricsInterval())
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{
+		Addr:    ":" + metricsPort(),
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Error running metrics HTTP server: %v", err)
+		}
+	}()
+}
+
+func pollMetrics(qmgrName string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := scrapeQueueStatus(qmgrName); err != nil {
+			log.Printf("Error scraping queue status for metrics: %v", err)
+		}
+		if err := scrapeQueueAttributes(qmgrName); err != nil {
+			log.Printf("Error scraping queue attributes for metrics: %v", err)
+		}
+		if err := scrapeQueueResetStats(qmgrName); err != nil {
+			log.Printf("Error scraping queue enqueue/dequeue counts for metrics: %v", err)
+		}
+		if err := scrapeChannelStatus(qmgrName); err != nil {
+			log.Printf("Error scraping channel status for metrics: %v", err)
+		}
+		if err := scrapeListenerStatus(qmgrName); err != nil {
+			log.Printf("Error scraping listener status for metrics: %v", err)
+		}
+		if err := scrapeQmgrUptime(qmgrName); err != nil {
+			log.Printf("Error scraping queue manager uptime for metrics: %v", err)
+		}
+	}
+}
+
+// runmqsc runs the given runmqsc command against qmgrName and returns its
+// combined output, with each object's attributes joined onto one line.
+// runmqsc prints an object's attributes across several lines below an
+// "AMQnnnnI:" header, so the per-line patterns below would otherwise never
+// see, for example, QUEUE(...) and CURDEPTH(...) together.
+func runmqsc(qmgrName string, command string) (string, error) {
+	cmd := exec.Command("runmqsc", qmgrName)
+	cmd.Stdin = strings.NewReader(command + "\n")
+	out, err := cmd.CombinedOutput()
+	var records []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(records) == 0 || strings.HasPrefix(strings.TrimSpace(line), "AMQ") {
+			records = append(records, line)
+		} else {
+			records[len(records)-1] += " " + line
+		}
+	}
+	return strings.Join(records, "\n"), err
+}
+
+// qstatusPattern parses "DISPLAY QSTATUS" output.  Note that MAXDEPTH is a
+// queue attribute, not a status attribute: runmqsc reports it from
+// "DISPLAY QUEUE", not "DISPLAY QSTATUS" (see queueDepthAttrPattern below).
+var qstatusPattern = regexp.MustCompile(`QUEUE\(([^)]*)\).*CURDEPTH\(([-0-9]+)\).*IPPROCS\(([-0-9]+)\).*OPPROCS\(([-0-9]+)\)`)
+
+func scrapeQueueStatus(qmgrName string) error {
+	out, err := runmqsc(qmgrName, "DISPLAY QSTATUS(*) TYPE(QUEUE) CURDEPTH IPPROCS OPPROCS")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		m := qstatusPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		queue := strings.TrimSpace(m[1])
+		queueDepth.WithLabelValues(qmgrName, queue).Set(parseFloat(m[2]))
+		queueOpenInputCount.WithLabelValues(qmgrName, queue).Set(parseFloat(m[3]))
+		queueOpenOutputCount.WithLabelValues(qmgrName, queue).Set(parseFloat(m[4]))
+	}
+	return scanner.Err()
+}
+
+var queueDepthAttrPattern = regexp.MustCompile(`QUEUE\(([^)]*)\).*MAXDEPTH\(([-0-9]+)\)`)
+
+func scrapeQueueAttributes(qmgrName string) error {
+	out, err := runmqsc(qmgrName, "DISPLAY QUEUE(*) MAXDEPTH")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		m := queueDepthAttrPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		queue := strings.TrimSpace(m[1])
+		queueMaxDepth.WithLabelValues(qmgrName, queue).Set(parseFloat(m[2]))
+	}
+	return scanner.Err()
+}
+
+var resetQStatsPattern = regexp.MustCompile(`QUEUE\(([^)]*)\).*ENQCOUNT\(([-0-9]+)\).*DEQCOUNT\(([-0-9]+)\)`)
+
+func scrapeQueueResetStats(qmgrName string) error {
+	out, err := runmqsc(qmgrName, "RESET QSTATS(*)")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		m := resetQStatsPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		queue := strings.TrimSpace(m[1])
+		queueEnqueueCount.WithLabelValues(qmgrName, queue).Add(parseFloat(m[2]))
+		queueDequeueCount.WithLabelValues(qmgrName, queue).Add(parseFloat(m[3]))
+	}
+	return scanner.Err()
+}
+
+var chstatusPattern = regexp.MustCompile(`CHANNEL\(([^)]*)\).*STATUS\(([A-Z]+)\)`)
+
+func scrapeChannelStatus(qmgrName string) error {
+	out, err := runmqsc(qmgrName, "DISPLAY CHSTATUS(*) STATUS")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		m := chstatusPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		channel := strings.TrimSpace(m[1])
+		channelStatus.WithLabelValues(qmgrName, channel).Set(channelStatusCode(m[2]))
+	}
+	return scanner.Err()
+}
+
+// channelStatusCode maps the textual STATUS reported by runmqsc onto the
+// numeric MQCHS_* constants, so the metric stays a gauge rather than a
+// label explosion.
+func channelStatusCode(status string) float64 {
+	switch status {
+	case "RUNNING":
+		return 3 // MQCHS_RUNNING
+	case "STARTING":
+		return 2 // MQCHS_STARTING
+	case "STOPPED":
+		return 6 // MQCHS_STOPPED
+	case "RETRYING":
+		return 5 // MQCHS_RETRYING
+	case "PAUSED":
+		return 8 // MQCHS_PAUSED
+	default:
+		return 0 // MQCHS_INACTIVE
+	}
+}
+
+var lsstatusPattern = regexp.MustCompile(`LISTENER\(([^)]*)\).*STATUS\(([A-Z]+)\)`)
+
+func scrapeListenerStatus(qmgrName string) error {
+	out, err := runmqsc(qmgrName, "DISPLAY LSSTATUS(*) STATUS")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		m := lsstatusPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		listener := strings.TrimSpace(m[1])
+		status := 0.0 // MQSVC_STATUS_STOPPED
+		if m[2] == "RUNNING" {
+			status = 2 // MQSVC_STATUS_RUNNING
+		}
+		listenerStatus.WithLabelValues(qmgrName, listener).Set(status)
+	}
+	return scanner.Err()
+}
+
+var dspmqStartTimePattern = regexp.MustCompile(`\(([0-9:]+)\s+([0-9/]+)\)`)
+
+func scrapeQmgrUptime(qmgrName string) error {
+	out, err := exec.Command("dspmq", "-o", "status").CombinedOutput()
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.Contains(line, "QMNAME("+qmgrName+")") {
+			continue
+		}
+		m := dspmqStartTimePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		started, err := time.ParseInLocation("15:04:05 01/02/2006", m[1]+" "+m[2], time.Local)
+		if err != nil {
+			return err
+		}
+		qmgrUptimeSeconds.WithLabelValues(qmgrName).Set(time.Since(started).Seconds())
+	}
+	return nil
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,118 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writablePaths lists the directories runmqserver itself writes to outside
+// of /mnt/mqm (the queue manager's persisted data, which already has its
+// own volume).  When the container is started with a read-only root
+// filesystem, each of these must instead be a tmpfs mount or a named
+// volume supplied by the caller; prepareReadOnlyRoot creates each of these
+// directories, and the overlay for rendered configuration, if it doesn't
+// already exist, but doesn't mount anything itself.
+var writablePaths = []string{
+	"/tmp",
+	"/run",
+	"/var/mqm/errors",
+	"/var/mqm/trace",
+}
+
+// mqscOverlayDir is where rendered MQSC/.ini templates (see mqtemplate) are
+// written when the root filesystem is read-only, instead of back into
+// /etc/mqm, which may not be writable.
+const mqscOverlayDir = "/run/mqm/etc-overlay"
+
+// readOnlyRootEnabled returns true if MQ_READONLY_ROOTFS indicates the
+// container expects a read-only root filesystem (Docker's --read-only).
+func readOnlyRootEnabled() bool {
+	return os.Getenv("MQ_READONLY_ROOTFS") == "true"
+}
+
+// prepareReadOnlyRoot creates each of writablePaths and the writable
+// overlay directory used for rendered configuration when running with a
+// read-only root filesystem. It must be called before any MQSC/.ini
+// templates are rendered.
+func prepareReadOnlyRoot() error {
+	if !readOnlyRootEnabled() {
+		return nil
+	}
+	for _, dir := range append(writablePaths, mqscOverlayDir) {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to prepare writable path %v for read-only root: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+// mqscConfigDir returns the directory runmqserver should read MQSC/.ini
+// source files from, and the directory rendered output should be written
+// to.  With a writable root they're the same directory (/etc/mqm); with a
+// read-only root, rendered output goes to the overlay instead.
+func mqscConfigDir() (source string, rendered string) {
+	if readOnlyRootEnabled() {
+		return "/etc/mqm", mqscOverlayDir
+	}
+	return "/etc/mqm", "/etc/mqm"
+}
+
+// copyReadOnly copies a source tree into a writable destination, used to
+// seed the overlay directory with any files that don't need rendering
+// (plain, non-template MQSC/.ini files) so runmqserver only has one
+// directory to scan.  Files handled by mqtemplate (*.mqsc.tmpl,
+// *.ini.tmpl) are skipped here; RenderAll writes their rendered output
+// into the same destination directory instead.
+func copyReadOnly(srcDir string, dstDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dstDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(dst, 0750)
+		}
+		if strings.HasSuffix(path, ".mqsc.tmpl") || strings.HasSuffix(path, ".ini.tmpl") {
+			return nil
+		}
+		return copyFile(path, dst)
+	})
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,142 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// defaultEndmqmTimeout is how long each endmqm mode gets to complete before
+// escalating to the next one, when MQ_ENDMQM_TIMEOUT isn't set.
+const defaultEndmqmTimeout = 30 * time.Second
+
+// endmqmEscalation is the sequence of endmqm modes tried on shutdown,
+// starting from whichever mode MQ_ENDMQM_MODE selects.  Each mode is given
+// endmqmTimeout() to complete before the next, stronger mode is tried.
+// "preempt" (-p) always runs last, since it doesn't wait for applications
+// to disconnect.
+var endmqmEscalation = []string{"quiesce", "immediate", "preempt"}
+
+// endmqmFlags maps an endmqm mode name onto its command-line flag.
+var endmqmFlags = map[string]string{
+	"quiesce":   "-c",
+	"immediate": "-i",
+	"preempt":   "-p",
+}
+
+// endmqmMode returns the configured starting shutdown mode, defaulting to
+// "immediate" to match the behaviour runmqserver had before this mode
+// became configurable.
+func endmqmMode() string {
+	if m := os.Getenv("MQ_ENDMQM_MODE"); m != "" {
+		if _, ok := endmqmFlags[m]; ok {
+			return m
+		}
+		log.Printf("Unknown MQ_ENDMQM_MODE %q; defaulting to immediate", m)
+	}
+	return "immediate"
+}
+
+// endmqmTimeout returns the configured per-mode timeout, or
+// defaultEndmqmTimeout.
+func endmqmTimeout() time.Duration {
+	if v := os.Getenv("MQ_ENDMQM_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultEndmqmTimeout
+}
+
+// escalationFrom returns the subsequence of endmqmEscalation starting at
+// mode, so that shutdown always escalates forward from the configured
+// starting mode and never weakens it.
+func escalationFrom(mode string) []string {
+	for i, m := range endmqmEscalation {
+		if m == mode {
+			return endmqmEscalation[i:]
+		}
+	}
+	return endmqmEscalation
+}
+
+// shutdownQueueManager stops qmgrName on receipt of SIGTERM, starting with
+// the mode selected by MQ_ENDMQM_MODE (default "immediate") and escalating
+// to the next stronger mode every MQ_ENDMQM_TIMEOUT (default 30s) until
+// endmqm succeeds, finally falling back to "endmqm -p".  It returns the rc
+// of the endmqm invocation that succeeded, or the rc of the last attempt if
+// every mode failed.
+func shutdownQueueManager(qmgrName string) int {
+	modes := escalationFrom(endmqmMode())
+	timeout := endmqmTimeout()
+	var rc int
+	for i, mode := range modes {
+		rc = runEndmqm(qmgrName, mode, timeout)
+		if rc == 0 {
+			return 0
+		}
+		if i < len(modes)-1 {
+			log.Printf("endmqm -%v did not complete within %v (rc=%v); escalating to %v", mode, timeout, rc, modes[i+1])
+		}
+	}
+	return rc
+}
+
+// runEndmqm runs "endmqm <flag> qmgrName" and waits up to timeout for it to
+// complete, killing it and reporting a non-zero rc if it doesn't.
+func runEndmqm(qmgrName string, mode string, timeout time.Duration) int {
+	cmd := exec.Command("endmqm", endmqmFlags[mode], qmgrName)
+	if err := cmd.Start(); err != nil {
+		log.Printf("Error starting endmqm: %v", err)
+		return -1
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return exitCode(err)
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		<-done
+		return 1
+	}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// handleSIGTERM is invoked by runmqserver's signal handler.  A non-zero
+// return means the caller should exit non-zero too, propagating the
+// underlying endmqm failure rather than masking it with a clean exit.
+func handleSIGTERM(qmgrName string) error {
+	rc := shutdownQueueManager(qmgrName)
+	if rc != 0 {
+		return fmt.Errorf("endmqm failed with rc=%v", rc)
+	}
+	return nil
+}
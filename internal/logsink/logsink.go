@@ -0,0 +1,308 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logsink forwards mirrored AMQERR0x.json entries to a sink other
+// than stdout, selected by MQ_LOG_SINK.  This lets the container forward
+// its error log somewhere centralized without requiring a separate log
+// shipper sidecar when it's run outside Kubernetes.
+package logsink
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// defaultQueueSize bounds how many formatted entries are held in memory
+// while waiting for a sink's connection; beyond this, the oldest queued
+// entry is dropped (see DroppedTotal) rather than blocking the caller.
+const defaultQueueSize = 1024
+
+// maxBatchSize and batchInterval bound how many queued entries run()
+// coalesces into a single flush: whichever limit is hit first triggers a
+// write, so a burst of entries doesn't wait the full interval but a slow
+// trickle still gets flushed promptly.
+const (
+	maxBatchSize  = 50
+	batchInterval = 200 * time.Millisecond
+)
+
+// Sink forwards mirrored error log entries somewhere other than stdout.
+type Sink interface {
+	// Send queues entry (a single AMQERR0x.json line) for delivery.  It
+	// never blocks the caller.
+	Send(entry []byte)
+	// Close flushes and closes the sink's connection.
+	Close() error
+}
+
+// noopSink is used for MQ_LOG_SINK=stdout (the default): mirroring to
+// stdout already happens independently of this package, so there's nothing
+// further to send.
+type noopSink struct{}
+
+func (noopSink) Send(entry []byte) {}
+func (noopSink) Close() error      { return nil }
+
+var droppedTotal uint64
+
+// DroppedTotal returns the number of entries dropped so far because a sink
+// couldn't keep up with the rate of incoming log entries.
+func DroppedTotal() uint64 {
+	return atomic.LoadUint64(&droppedTotal)
+}
+
+// NewFromEnv builds the Sink selected by MQ_LOG_SINK (one of "stdout"
+// (default), "syslog", "gelf" or "fluentd-forward"), delivering to the
+// destination configured via MQ_LOG_SINK_URL.
+func NewFromEnv() (Sink, error) {
+	kind := os.Getenv("MQ_LOG_SINK")
+	if kind == "" {
+		kind = "stdout"
+	}
+	rawURL := os.Getenv("MQ_LOG_SINK_URL")
+	switch kind {
+	case "stdout":
+		return noopSink{}, nil
+	case "syslog":
+		return newNetworkSink(rawURL, formatSyslog)
+	case "gelf":
+		return newNetworkSink(rawURL, formatGELF)
+	case "fluentd-forward":
+		return newNetworkSink(rawURL, formatFluentdForward)
+	default:
+		return nil, fmt.Errorf("unknown MQ_LOG_SINK %q", kind)
+	}
+}
+
+// formatter turns a raw AMQERR0x.json line into the wire format a sink
+// sends.
+type formatter func(entry []byte) ([]byte, error)
+
+// networkSink delivers formatted entries over a UDP, TCP or TLS
+// connection, reconnecting lazily on the next flush after a write failure.
+// Queued entries are coalesced into batches of up to maxBatchSize, flushed
+// at least every batchInterval; each entry in a batch is still sent as its
+// own write (one datagram per entry over UDP), so batching bounds how often
+// the connection is used rather than merging entries. When the queue is
+// full, the oldest entry is dropped to
+// make room for the newest one, so a slow or unreachable sink can never
+// back up log mirroring.
+type networkSink struct {
+	queue   chan []byte
+	format  formatter
+	target  string
+	network string
+	tlsCfg  *tls.Config
+	done    chan struct{}
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newNetworkSink(rawURL string, format formatter) (*networkSink, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("MQ_LOG_SINK_URL is required for this MQ_LOG_SINK")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MQ_LOG_SINK_URL %q: %v", rawURL, err)
+	}
+	var network string
+	var tlsCfg *tls.Config
+	switch u.Scheme {
+	case "udp":
+		network = "udp"
+	case "tcp":
+		network = "tcp"
+	case "tls":
+		network = "tcp"
+		tlsCfg = &tls.Config{}
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in MQ_LOG_SINK_URL", u.Scheme)
+	}
+	s := &networkSink{
+		queue:   make(chan []byte, defaultQueueSize),
+		format:  format,
+		target:  u.Host,
+		network: network,
+		tlsCfg:  tlsCfg,
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *networkSink) Send(entry []byte) {
+	formatted, err := s.format(entry)
+	if err != nil {
+		log.Printf("Error formatting log entry for sink: %v", err)
+		return
+	}
+	select {
+	case s.queue <- formatted:
+		return
+	default:
+	}
+	// The queue is full: drop the oldest entry to make room for this one.
+	select {
+	case <-s.queue:
+		atomic.AddUint64(&droppedTotal, 1)
+	default:
+	}
+	select {
+	case s.queue <- formatted:
+	default:
+		atomic.AddUint64(&droppedTotal, 1)
+	}
+}
+
+func (s *networkSink) Close() error {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func (s *networkSink) run() {
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.write(batch); err != nil {
+			log.Printf("Error writing to log sink, will reconnect: %v", err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case <-s.done:
+			flush()
+			return
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// write sends each entry in batch over the connection, in order. A write
+// failure drops the connection (so the next flush reconnects) and aborts
+// the rest of the batch; entries already written are not resent.
+func (s *networkSink) write(batch [][]byte) error {
+	conn, err := s.connection()
+	if err != nil {
+		return err
+	}
+	for _, entry := range batch {
+		if _, err := conn.Write(entry); err != nil {
+			s.mu.Lock()
+			s.conn = nil
+			s.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *networkSink) connection() (net.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return s.conn, nil
+	}
+	var conn net.Conn
+	var err error
+	if s.tlsCfg != nil {
+		conn, err = tls.Dial("tcp", s.target, s.tlsCfg)
+	} else {
+		conn, err = net.DialTimeout(s.network, s.target, 5*time.Second)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.conn = conn
+	return conn, nil
+}
+
+// formatSyslog renders entry as an RFC 5424 syslog message.
+func formatSyslog(entry []byte) ([]byte, error) {
+	msg, err := errorMessage(entry)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	line := fmt.Sprintf("<14>1 %v %v runmqserver %v - - %v\n",
+		time.Now().UTC().Format(time.RFC3339), hostname, strconv.Itoa(os.Getpid()), msg)
+	return []byte(line), nil
+}
+
+// formatGELF renders entry as a single, unchunked GELF 1.1 message.
+func formatGELF(entry []byte) ([]byte, error) {
+	msg, err := errorMessage(entry)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	gelf := map[string]interface{}{
+		"version":       "1.1",
+		"host":          hostname,
+		"short_message": msg,
+		"timestamp":     float64(time.Now().Unix()),
+	}
+	return json.Marshal(gelf)
+}
+
+// formatFluentdForward renders entry as a single Forward-protocol message
+// ([tag, time, record]), MessagePack-encoded.
+func formatFluentdForward(entry []byte) ([]byte, error) {
+	var record map[string]interface{}
+	if err := json.Unmarshal(entry, &record); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal([]interface{}{"mq.errorlog", time.Now().Unix(), record})
+}
+
+func errorMessage(entry []byte) (string, error) {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(entry, &parsed); err != nil {
+		return "", err
+	}
+	msg, _ := parsed["message"].(string)
+	return msg, nil
+}
@@ -0,0 +1,79 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logsink
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// TestNetworkSinkDeliversBatch checks that entries queued faster than
+// batchInterval are still all delivered, each as its own datagram.
+func TestNetworkSinkDeliversBatch(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := newNetworkSink("udp://"+conn.LocalAddr().String(), func(entry []byte) ([]byte, error) {
+		return entry, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	const n = 10
+	for i := 0; i < n; i++ {
+		sink.Send([]byte{byte(i)})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	received := 0
+	buf := make([]byte, 16)
+	for received < n {
+		if _, err := conn.Read(buf); err != nil {
+			t.Fatalf("Expected %v batched entries, got %v: %v", n, received, err)
+		}
+		received++
+	}
+}
+
+// TestNetworkSinkDropsOldestWhenFull checks that once the queue is full,
+// sending another entry drops the oldest one and increments DroppedTotal,
+// instead of blocking the caller.
+func TestNetworkSinkDropsOldestWhenFull(t *testing.T) {
+	s := &networkSink{
+		queue:  make(chan []byte, 2),
+		format: func(entry []byte) ([]byte, error) { return entry, nil },
+		done:   make(chan struct{}),
+	}
+	before := DroppedTotal()
+
+	s.Send([]byte("one"))
+	s.Send([]byte("two"))
+	s.Send([]byte("three"))
+
+	if got := DroppedTotal(); got != before+1 {
+		t.Errorf("Expected DroppedTotal to increase by 1, got %v (was %v)", got, before)
+	}
+	if len(s.queue) != 2 {
+		t.Errorf("Expected the queue to stay at capacity 2, got %v", len(s.queue))
+	}
+}
@@ -0,0 +1,141 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mqtemplate renders the .mqsc.tmpl and .ini.tmpl files under
+// /etc/mqm through text/template before they're applied, so that a single
+// image can be parametrized for several environments instead of requiring
+// one image build per environment.
+package mqtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateSuffixes lists the file extensions that are rendered before use.
+// The rendered file is written under RenderAll's outDir, with the ".tmpl"
+// suffix stripped (e.g. "qm.mqsc.tmpl" becomes "qm.mqsc").
+var templateSuffixes = []string{".mqsc.tmpl", ".ini.tmpl"}
+
+// data is the root object made available to templates as `.`.
+type data struct {
+	// Env holds the process environment, so templates can refer to
+	// {{ .Env.MQ_QMGR_NAME }}.
+	Env map[string]string
+}
+
+// RenderAll walks srcDir looking for files ending in .mqsc.tmpl or
+// .ini.tmpl, renders each one through text/template, and writes the result
+// under outDir (preserving the path relative to srcDir) with the .tmpl
+// suffix removed.  outDir may be the same directory as srcDir, for a
+// writable /etc/mqm, or a separate overlay directory when the root
+// filesystem is read-only (see readonlyroot.go).  It returns the first
+// error encountered, wrapped with enough context for a caller to print a
+// clear termination message and exit.
+func RenderAll(srcDir string, outDir string) error {
+	env := data{Env: environToMap(os.Environ())}
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		suffix, ok := matchSuffix(path)
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		out := strings.TrimSuffix(filepath.Join(outDir, rel), ".tmpl")
+		if err := os.MkdirAll(filepath.Dir(out), 0750); err != nil {
+			return fmt.Errorf("failed to create %v for rendered template %v: %v", filepath.Dir(out), path, err)
+		}
+		if err := renderFile(path, out, env); err != nil {
+			return fmt.Errorf("failed to render template %v (%v): %v", path, suffix, err)
+		}
+		return nil
+	})
+}
+
+func matchSuffix(path string) (string, bool) {
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return suffix, true
+		}
+	}
+	return "", false
+}
+
+func renderFile(in string, out string, env data) error {
+	tmpl, err := template.New(filepath.Base(in)).Funcs(funcMap(env)).ParseFiles(in)
+	if err != nil {
+		return err
+	}
+	// Render into memory first, so a failed template never leaves a
+	// partially written file behind.
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, filepath.Base(in), env); err != nil {
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0640)
+}
+
+// funcMap returns the helper functions made available to templates, in
+// addition to the `.Env` value on the root data object.
+func funcMap(env data) template.FuncMap {
+	return template.FuncMap{
+		"getenv": func(name string) string {
+			return env.Env[name]
+		},
+		"required": func(name string, value string) (string, error) {
+			if value == "" {
+				return "", errors.New(name + " is required but was empty")
+			}
+			return value, nil
+		},
+		"default": func(def string, value string) string {
+			if value == "" {
+				return def
+			}
+			return value
+		},
+		"splitList": func(sep string, value string) []string {
+			if value == "" {
+				return nil
+			}
+			return strings.Split(value, sep)
+		},
+	}
+}
+
+func environToMap(environ []string) map[string]string {
+	m := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			m[parts[0]] = parts[1]
+		}
+	}
+	return m
+}
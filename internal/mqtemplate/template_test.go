@@ -0,0 +1,72 @@
+/*
+© Copyright IBM Corporation 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRenderAllWritesToOutDir checks that RenderAll renders a .mqsc.tmpl
+// file found in srcDir into outDir, even when outDir is a separate
+// directory (as required when the root filesystem is read-only).
+func TestRenderAllWritesToOutDir(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	os.Setenv("MQ_TEMPLATE_TEST_QUEUE", "ONE,TWO")
+	defer os.Unsetenv("MQ_TEMPLATE_TEST_QUEUE")
+
+	src := "DEFINE QLOCAL({{ .Env.MQ_QMGR_NAME }})\n{{- range splitList \",\" (getenv \"MQ_TEMPLATE_TEST_QUEUE\") }}\nDEFINE QLOCAL({{ . }})\n{{- end }}\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "test.mqsc.tmpl"), []byte(src), 0640); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MQ_QMGR_NAME", "qm1")
+	defer os.Unsetenv("MQ_QMGR_NAME")
+
+	if err := RenderAll(srcDir, outDir); err != nil {
+		t.Fatalf("Expected RenderAll to succeed, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(srcDir, "test.mqsc")); err == nil {
+		t.Error("Expected rendered output not to be written back into srcDir")
+	}
+	out, err := os.ReadFile(filepath.Join(outDir, "test.mqsc"))
+	if err != nil {
+		t.Fatalf("Expected rendered output in outDir, got %v", err)
+	}
+	for _, want := range []string{"DEFINE QLOCAL(qm1)", "DEFINE QLOCAL(ONE)", "DEFINE QLOCAL(TWO)"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected rendered output to contain %q, got %v", want, string(out))
+		}
+	}
+}
+
+// TestRenderAllRequiredError checks that a missing `required` value causes
+// RenderAll to return an error, rather than writing out incomplete MQSC.
+func TestRenderAllRequiredError(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	src := `DEFINE QLOCAL({{ required "MQ_TEMPLATE_TEST_MISSING" (getenv "MQ_TEMPLATE_TEST_MISSING") }})`
+	if err := os.WriteFile(filepath.Join(srcDir, "test.mqsc.tmpl"), []byte(src), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenderAll(srcDir, outDir); err == nil {
+		t.Fatal("Expected RenderAll to fail when a required value is missing")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -30,6 +31,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
@@ -190,6 +192,62 @@ func TestWithVolume(t *testing.T) {
 	waitForReady(t, cli, ctr2.ID)
 }
 
+// TestReadOnlyRootfs runs a container with Docker's --read-only and the
+// tmpfs mounts it needs, and checks that the queue manager still reaches
+// ready, that runmqsc works against it, and that chkmqhealthy continues to
+// pass after a restart of the same container.
+func TestReadOnlyRootfs(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := createVolume(t, cli)
+	defer removeVolume(t, cli, vol.Name)
+	containerConfig := container.Config{
+		Image: imageName(),
+		Env: []string{
+			"LICENSE=accept",
+			"MQ_QMGR_NAME=qm1",
+			"MQ_READONLY_ROOTFS=true",
+		},
+	}
+	hostConfig := container.HostConfig{
+		ReadonlyRootfs: true,
+		Tmpfs: map[string]string{
+			"/tmp":            "",
+			"/run":            "",
+			"/var/mqm/errors": "",
+			"/var/mqm/trace":  "",
+		},
+		Binds: []string{
+			coverageBind(t),
+			vol.Name + ":/mnt/mqm",
+		},
+	}
+	networkingConfig := network.NetworkingConfig{}
+	ctr, err := cli.ContainerCreate(context.Background(), &containerConfig, &hostConfig, &networkingConfig, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, ctr.ID)
+	defer cleanContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+
+	rc, _ := execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "echo 'DISPLAY QMGR' | runmqsc"})
+	if rc != 0 {
+		t.Fatalf("Expected runmqsc to work with a read-only root filesystem, got rc=%v", rc)
+	}
+
+	stopContainer(t, cli, ctr.ID)
+	startContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+	rc, _ = execContainer(t, cli, ctr.ID, "mqm", []string{"chkmqhealthy"})
+	if rc != 0 {
+		t.Errorf("Expected chkmqhealthy to pass after restart, got rc=%v", rc)
+	}
+}
+
 // TestNoVolumeWithRestart ensures a queue manager container can be stopped
 // and restarted cleanly
 func TestNoVolumeWithRestart(t *testing.T) {
@@ -209,6 +267,65 @@ func TestNoVolumeWithRestart(t *testing.T) {
 	waitForReady(t, cli, id)
 }
 
+// TestGracefulShutdownEscalation puts persistent messages onto a queue,
+// then stops the container with MQ_ENDMQM_TIMEOUT=1s, so that runmqserver
+// escalates from the default "immediate" endmqm mode to "preempt" if
+// "immediate" hasn't completed within a second.  The queue manager should
+// still restart cleanly afterwards with the messages intact.
+func TestGracefulShutdownEscalation(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := createVolume(t, cli)
+	defer removeVolume(t, cli, vol.Name)
+	containerConfig := container.Config{
+		Image: imageName(),
+		Env: []string{
+			"LICENSE=accept",
+			"MQ_QMGR_NAME=qm1",
+			"MQ_ENDMQM_TIMEOUT=1s",
+		},
+	}
+	hostConfig := container.HostConfig{
+		Binds: []string{
+			coverageBind(t),
+			vol.Name + ":/mnt/mqm",
+		},
+	}
+	networkingConfig := network.NetworkingConfig{}
+	ctr, err := cli.ContainerCreate(context.Background(), &containerConfig, &hostConfig, &networkingConfig, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, ctr.ID)
+	defer cleanContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+
+	execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "echo 'DEFINE QLOCAL(PERSIST.TEST) DEFPSIST(YES)' | runmqsc qm1"})
+	execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "echo hello | /opt/mqm/samp/bin/amqsput PERSIST.TEST qm1"})
+
+	// The 2s grace period leaves time for the 1s MQ_ENDMQM_TIMEOUT to
+	// escalate past "immediate" to "preempt" before docker kills the
+	// container.
+	timeout := 2 * time.Second
+	if err := cli.ContainerStop(context.Background(), ctr.ID, &timeout); err != nil {
+		t.Fatalf("Expected container to stop, got %v", err)
+	}
+	rc := waitForContainer(t, cli, ctr.ID, 30)
+	if rc != 0 {
+		t.Errorf("Expected rc=0 after graceful shutdown, got %v", rc)
+	}
+
+	startContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+	rc, out := execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "/opt/mqm/samp/bin/amqsget PERSIST.TEST qm1"})
+	if rc != 0 || !strings.Contains(out, "hello") {
+		t.Errorf("Expected persistent message to survive restart, got rc=%v out=%v", rc, out)
+	}
+}
+
 // TestCreateQueueManagerFail causes a failure of `crtmqm`
 func TestCreateQueueManagerFail(t *testing.T) {
 	t.Parallel()
@@ -420,6 +537,233 @@ func TestReadiness(t *testing.T) {
 	}
 }
 
+// TestHealthHTTP starts a queue manager with the HTTP health endpoints
+// enabled, and checks that /ready, /live and /started report healthy, then
+// that /live reports unhealthy once the queue manager's volume is
+// unmounted (see TestVolumeUnmount).
+func TestHealthHTTP(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := createVolume(t, cli)
+	defer removeVolume(t, cli, vol.Name)
+	const healthPort = nat.Port("9157/tcp")
+	containerConfig := container.Config{
+		Image: imageName(),
+		Env: []string{
+			"LICENSE=accept",
+			"MQ_QMGR_NAME=qm1",
+			"MQ_ENABLE_HEALTH_HTTP=true",
+		},
+		ExposedPorts: nat.PortSet{
+			healthPort: struct{}{},
+		},
+	}
+	hostConfig := container.HostConfig{
+		// SYS_ADMIN capability is required to unmount file systems
+		CapAdd: []string{
+			"SYS_ADMIN",
+		},
+		Binds: []string{
+			coverageBind(t),
+			vol.Name + ":/mnt/mqm",
+		},
+		PortBindings: nat.PortMap{
+			healthPort: []nat.PortBinding{{HostIP: "0.0.0.0"}},
+		},
+	}
+	networkingConfig := network.NetworkingConfig{}
+	ctr, err := cli.ContainerCreate(context.Background(), &containerConfig, &hostConfig, &networkingConfig, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, ctr.ID)
+	defer cleanContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+
+	inspect, err := cli.ContainerInspect(context.Background(), ctr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindings := inspect.NetworkSettings.Ports[healthPort]
+	if len(bindings) == 0 {
+		t.Fatal("Expected health port to be published")
+	}
+	base := "http://127.0.0.1:" + bindings[0].HostPort
+
+	assertHealthStatus(t, base+"/ready", http.StatusOK)
+	assertHealthStatus(t, base+"/live", http.StatusOK)
+	assertHealthStatus(t, base+"/started", http.StatusOK)
+
+	// Unmount the volume as root; /live should then report unhealthy
+	rc, _ := execContainer(t, cli, ctr.ID, "root", []string{"umount", "-l", "-f", "/mnt/mqm"})
+	if rc != 0 {
+		t.Fatalf("Expected umount to work with rc=0, got %v", rc)
+	}
+	time.Sleep(3 * time.Second)
+	assertHealthStatus(t, base+"/live", http.StatusServiceUnavailable)
+}
+
+func assertHealthStatus(t *testing.T, url string, expected int) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Expected GET %v to succeed, got %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expected {
+		t.Errorf("Expected GET %v to return %v, got %v", url, expected, resp.StatusCode)
+	}
+}
+
+// TestMetricsEndpoint starts a queue manager with the Prometheus exporter
+// enabled, puts some messages onto a queue with amqsput, scrapes /metrics
+// and checks that the queue depth metric reflects the messages put.
+func TestMetricsEndpoint(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const metricsPort = nat.Port("9308/tcp")
+	containerConfig := container.Config{
+		Image: imageName(),
+		Env: []string{
+			"LICENSE=accept",
+			"MQ_QMGR_NAME=qm1",
+			"MQ_ENABLE_METRICS=true",
+			"MQ_METRICS_INTERVAL=1s",
+		},
+		ExposedPorts: nat.PortSet{
+			metricsPort: struct{}{},
+		},
+	}
+	hostConfig := container.HostConfig{
+		Binds: []string{
+			coverageBind(t),
+		},
+		PortBindings: nat.PortMap{
+			metricsPort: []nat.PortBinding{{HostIP: "0.0.0.0"}},
+		},
+	}
+	networkingConfig := network.NetworkingConfig{}
+	ctr, err := cli.ContainerCreate(context.Background(), &containerConfig, &hostConfig, &networkingConfig, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, ctr.ID)
+	defer cleanContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+
+	execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "echo 'DEFINE QLOCAL(METRICS.TEST)' | runmqsc qm1"})
+	for i := 0; i < 5; i++ {
+		execContainer(t, cli, ctr.ID, "mqm", []string{"bash", "-c", "echo msg" + strconv.Itoa(i) + " | /opt/mqm/samp/bin/amqsput METRICS.TEST qm1"})
+	}
+	// Allow the poller at least one tick
+	time.Sleep(3 * time.Second)
+
+	inspect, err := cli.ContainerInspect(context.Background(), ctr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindings := inspect.NetworkSettings.Ports[metricsPort]
+	if len(bindings) == 0 {
+		t.Fatal("Expected metrics port to be published")
+	}
+	resp, err := http.Get("http://127.0.0.1:" + bindings[0].HostPort + "/metrics")
+	if err != nil {
+		t.Fatalf("Expected GET /metrics to succeed, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(body)
+	for _, want := range []string{
+		`mq_queue_depth{qmgr="qm1",queue="METRICS.TEST"} 5`,
+		`mq_queue_max_depth{qmgr="qm1",queue="METRICS.TEST"}`,
+		`mq_queue_msg_enqueue_count{qmgr="qm1",queue="METRICS.TEST"} 5`,
+		`mq_channel_status{qmgr="qm1"`,
+		`mq_qmgr_uptime_seconds{qmgr="qm1"}`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected /metrics output to contain %q, got:\n%v", want, text)
+		}
+	}
+}
+
+// TestMQSCTemplate creates a new image with a .mqsc.tmpl file in, and
+// checks that it's rendered through text/template (picking up .Env and a
+// splitList'd env var) before being applied.
+func TestMQSCTemplate(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const tmpl = `DEFINE QLOCAL({{ .Env.MQ_QMGR_NAME }}.DEFAULT)
+{{- range splitList "," (getenv "EXTRA_QUEUES") }}
+DEFINE QLOCAL({{ . }})
+{{- end }}
+`
+	var files = []struct {
+		Name, Body string
+	}{
+		{"Dockerfile", fmt.Sprintf("FROM %v\nRUN rm -f /etc/mqm/*.mqsc\nADD test.mqsc.tmpl /etc/mqm/", imageName())},
+		{"test.mqsc.tmpl", tmpl},
+	}
+	tag := createImage(t, cli, files)
+	defer deleteImage(t, cli, tag)
+
+	containerConfig := container.Config{
+		Env:   []string{"LICENSE=accept", "MQ_QMGR_NAME=qm1", "EXTRA_QUEUES=ONE,TWO"},
+		Image: tag,
+	}
+	id := runContainer(t, cli, &containerConfig)
+	defer cleanContainer(t, cli, id)
+	waitForReady(t, cli, id)
+
+	for _, queue := range []string{"qm1.DEFAULT", "ONE", "TWO"} {
+		rc, _ := execContainer(t, cli, id, "mqm", []string{"bash", "-c", "echo 'DISPLAY QLOCAL(" + queue + ")' | runmqsc"})
+		if rc != 0 {
+			t.Fatalf("Expected runmqsc to find queue %v, got rc=%v", queue, rc)
+		}
+	}
+}
+
+// TestMQSCTemplateError checks that an error while rendering a template
+// (here, a required value left unset) causes a clean termination with a
+// clear message, rather than starting with incomplete configuration.
+func TestMQSCTemplateError(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files = []struct {
+		Name, Body string
+	}{
+		{"Dockerfile", fmt.Sprintf("FROM %v\nRUN rm -f /etc/mqm/*.mqsc\nADD test.mqsc.tmpl /etc/mqm/", imageName())},
+		{"test.mqsc.tmpl", `DEFINE QLOCAL({{ required "MQ_CUSTOM_QUEUE" (getenv "MQ_CUSTOM_QUEUE") }})`},
+	}
+	tag := createImage(t, cli, files)
+	defer deleteImage(t, cli, tag)
+
+	containerConfig := container.Config{
+		Env:   []string{"LICENSE=accept", "MQ_QMGR_NAME=qm1"},
+		Image: tag,
+	}
+	id := runContainer(t, cli, &containerConfig)
+	defer cleanContainer(t, cli, id)
+	rc := waitForContainer(t, cli, id, 10)
+	if rc != 1 {
+		t.Errorf("Expected rc=1, got rc=%v", rc)
+	}
+	expectTerminationMessage(t)
+}
+
 func countLines(t *testing.T, r io.Reader) int {
 	scanner := bufio.NewScanner(r)
 	count := 0
@@ -517,6 +861,74 @@ func TestErrorLogRotation(t *testing.T) {
 	}
 }
 
+// TestLogSinkForwarding starts a throwaway netcat sidecar on a
+// user-defined network, points the queue manager's GELF log sink at it,
+// generates error log content the way TestErrorLogRotation does (a single
+// unauthorized amqsput), and checks that the sidecar received at least one
+// forwarded GELF entry.
+func TestLogSinkForwarding(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	netResp, err := cli.NetworkCreate(context.Background(), t.Name(), types.NetworkCreate{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.NetworkRemove(context.Background(), netResp.ID)
+
+	sidecarConfig := container.Config{
+		Image: "busybox",
+		Cmd:   []string{"sh", "-c", "nc -u -l -p 12201"},
+	}
+	sidecarNetworking := network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			t.Name(): {Aliases: []string{"sink"}},
+		},
+	}
+	sidecar, err := cli.ContainerCreate(context.Background(), &sidecarConfig, &container.HostConfig{}, &sidecarNetworking, t.Name()+"-sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, sidecar.ID)
+	defer cleanContainer(t, cli, sidecar.ID)
+
+	qmName := "qm1"
+	containerConfig := container.Config{
+		Image: imageName(),
+		Env: []string{
+			"LICENSE=accept",
+			"MQ_QMGR_NAME=" + qmName,
+			"LOG_FORMAT=json",
+			"MQ_LOG_SINK=gelf",
+			"MQ_LOG_SINK_URL=udp://sink:12201",
+		},
+	}
+	qmNetworking := network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			t.Name(): {},
+		},
+	}
+	ctr, err := cli.ContainerCreate(context.Background(), &containerConfig, &container.HostConfig{}, &qmNetworking, t.Name()+"-qm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startContainer(t, cli, ctr.ID)
+	defer cleanContainer(t, cli, ctr.ID)
+	waitForReady(t, cli, ctr.ID)
+
+	// Generate some content for the error logs, as in TestErrorLogRotation
+	execContainer(t, cli, ctr.ID, "root", []string{"useradd", "fred"})
+	execContainer(t, cli, ctr.ID, "fred", []string{"bash", "-c", "/opt/mqm/samp/bin/amqsput FAKE"})
+	time.Sleep(3 * time.Second)
+
+	sinkLog := inspectLogs(t, cli, sidecar.ID)
+	if !strings.Contains(sinkLog, "short_message") {
+		t.Errorf("Expected sidecar to receive at least one forwarded GELF entry, got log:\n%v", sinkLog)
+	}
+}
+
 func TestJSONLogFormat(t *testing.T) {
 	t.Parallel()
 	cli, err := client.NewEnvClient()